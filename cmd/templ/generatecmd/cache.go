@@ -0,0 +1,108 @@
+package generatecmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/a-h/templ"
+	"github.com/cespare/xxhash/v2"
+)
+
+// cacheFileName is where the content hash cache is persisted, relative to
+// the module root.
+const cacheFileName = ".templ/cache.json"
+
+// changeCache tracks a content hash per .templ file, persisted to disk so
+// that generation is skipped for files whose content hasn't actually
+// changed, even across separate templ invocations (a `touch`, or a
+// checkout that only updates mtimes, shouldn't trigger regeneration).
+type changeCache struct {
+	path   string
+	noSave bool
+
+	Version string            `json:"version"`
+	Hashes  map[string]uint64 `json:"hashes"`
+}
+
+// loadChangeCache loads the cache for the module rooted at root. If
+// noCache is true, an empty, unpersisted cache is returned, so every file
+// is treated as changed the first time it's seen and nothing is written
+// back to disk. A cache
+// written by a different templ version is discarded, since the generated
+// output format may have changed.
+func loadChangeCache(root string, noCache bool) *changeCache {
+	c := &changeCache{
+		path:   filepath.Join(root, cacheFileName),
+		noSave: noCache,
+		Hashes: make(map[string]uint64),
+	}
+	if noCache {
+		return c
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+	var onDisk changeCache
+	if err := json.Unmarshal(data, &onDisk); err != nil {
+		return c
+	}
+	if onDisk.Version != templ.Version() {
+		return c
+	}
+	c.Hashes = onDisk.Hashes
+	return c
+}
+
+// changed reports whether fileName's contents no longer match the cached
+// hash, updating the cache with the new hash as a side effect. A file
+// that's never been seen before is always reported as changed.
+func (c *changeCache) changed(fileName string, contents []byte) bool {
+	hash := xxhash.Sum64(contents)
+	if existing, ok := c.Hashes[fileName]; ok && existing == hash {
+		return false
+	}
+	c.Hashes[fileName] = hash
+	return true
+}
+
+// forget removes fileName from the cache, e.g. because it was deleted.
+func (c *changeCache) forget(fileName string) {
+	delete(c.Hashes, fileName)
+}
+
+// save persists the cache to disk, unless it was loaded with noCache set.
+func (c *changeCache) save() error {
+	if c.noSave {
+		return nil
+	}
+	c.Version = templ.Version()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// moduleRoot walks up from dir until it finds a directory containing a
+// go.mod file, falling back to dir itself if none is found.
+func moduleRoot(dir string) string {
+	start, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for current := start; ; {
+		if _, err := os.Stat(filepath.Join(current, "go.mod")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return start
+		}
+		current = parent
+	}
+}
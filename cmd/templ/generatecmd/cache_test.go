@@ -0,0 +1,70 @@
+package generatecmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangeCacheDetectsContentChanges(t *testing.T) {
+	c := loadChangeCache(t.TempDir(), false)
+
+	fileName := "component.templ"
+	if !c.changed(fileName, []byte("a")) {
+		t.Error("expected a file seen for the first time to be reported as changed")
+	}
+	if c.changed(fileName, []byte("a")) {
+		t.Error("expected unchanged content to be reported as unchanged")
+	}
+	if !c.changed(fileName, []byte("b")) {
+		t.Error("expected changed content to be reported as changed")
+	}
+}
+
+func TestChangeCacheForget(t *testing.T) {
+	c := loadChangeCache(t.TempDir(), false)
+
+	fileName := "component.templ"
+	c.changed(fileName, []byte("a"))
+	c.forget(fileName)
+	if !c.changed(fileName, []byte("a")) {
+		t.Error("expected a forgotten file to be reported as changed, even with its previous content")
+	}
+}
+
+func TestLoadChangeCacheNoCacheDoesNotPersist(t *testing.T) {
+	root := t.TempDir()
+	c := loadChangeCache(root, true)
+
+	c.changed("component.templ", []byte("a"))
+	if err := c.save(); err != nil {
+		t.Fatalf("save returned an error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, cacheFileName)); err == nil {
+		t.Error("expected save to be a no-op when the cache was loaded with noCache set")
+	}
+}
+
+func TestChangeCacheSaveAndReload(t *testing.T) {
+	root := t.TempDir()
+	c := loadChangeCache(root, false)
+
+	fileName := "component.templ"
+	c.changed(fileName, []byte("a"))
+	if err := c.save(); err != nil {
+		t.Fatalf("save returned an error: %v", err)
+	}
+
+	reloaded := loadChangeCache(root, false)
+	if reloaded.changed(fileName, []byte("a")) {
+		t.Error("expected the hash persisted by save to survive a reload")
+	}
+}
+
+func TestModuleRoot(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if got := moduleRoot(sub); got != sub {
+		t.Errorf("expected moduleRoot to fall back to dir when no go.mod is found, got %q, want %q", got, sub)
+	}
+}
@@ -0,0 +1,165 @@
+package generatecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/a-h/templ/parser/v2"
+)
+
+const (
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+)
+
+// DiagnosticSink receives the result of generating a single file: any
+// diagnostics produced while parsing it, or the error if generation
+// failed outright (a parse error, a write failure, and so on). Routing
+// both through the same sink lets processSingleFile and the watch loop
+// report results identically, regardless of whether they came from a
+// single bad `if` block or a file that couldn't be written at all.
+// fixes is parallel to diags; fixes[i] is the suggested Fix for diags[i],
+// or nil if none applies.
+type DiagnosticSink interface {
+	Publish(w io.Writer, fileName string, diags []parser.Diagnostic, fixes []*Fix, err error) error
+}
+
+// NewDiagnosticSink returns the DiagnosticSink for the --diagnostics-format
+// flag value. An empty string or "text" keeps templ's existing
+// human-oriented console output; "json" emits one NDJSON object per
+// diagnostic; "lsp" wraps them in an LSP PublishDiagnosticsParams envelope.
+func NewDiagnosticSink(format string) (DiagnosticSink, error) {
+	switch format {
+	case "", "text":
+		return textDiagnosticSink{}, nil
+	case "json":
+		return jsonDiagnosticSink{}, nil
+	case "lsp":
+		return lspDiagnosticSink{}, nil
+	default:
+		return nil, fmt.Errorf("unknown diagnostics format %q, expected one of: text, json, lsp", format)
+	}
+}
+
+// textDiagnosticSink is the default sink, printing the same human-oriented
+// lines templ has always printed to the console.
+type textDiagnosticSink struct{}
+
+func (textDiagnosticSink) Publish(w io.Writer, fileName string, diags []parser.Diagnostic, fixes []*Fix, err error) error {
+	if err != nil {
+		logError(w, "%v\n", err)
+		return nil
+	}
+	if len(diags) > 0 {
+		printDiagnostics(w, fileName, diags, fixes)
+	}
+	return nil
+}
+
+type diagnosticRange struct {
+	Start diagnosticPosition `json:"start"`
+	End   diagnosticPosition `json:"end"`
+}
+
+type diagnosticPosition struct {
+	Line      uint32 `json:"line"`
+	Character uint32 `json:"character"`
+}
+
+func toDiagnosticRange(d parser.Diagnostic) diagnosticRange {
+	return diagnosticRange{
+		Start: diagnosticPosition{Line: d.Range.From.Line, Character: d.Range.From.Col},
+		End:   diagnosticPosition{Line: d.Range.To.Line, Character: d.Range.To.Col},
+	}
+}
+
+// jsonDiagnostic is one line of NDJSON output for --diagnostics-format=json.
+type jsonDiagnostic struct {
+	File     string          `json:"file"`
+	Range    diagnosticRange `json:"range"`
+	Severity string          `json:"severity"`
+	Message  string          `json:"message"`
+	Source   string          `json:"source"`
+	Fix      *Fix            `json:"fix,omitempty"`
+}
+
+type jsonDiagnosticSink struct{}
+
+func (jsonDiagnosticSink) Publish(w io.Writer, fileName string, diags []parser.Diagnostic, fixes []*Fix, err error) error {
+	enc := json.NewEncoder(w)
+	if err != nil {
+		return enc.Encode(jsonDiagnostic{
+			File:     fileName,
+			Severity: "error",
+			Message:  err.Error(),
+			Source:   "templ",
+		})
+	}
+	for i, d := range diags {
+		jd := jsonDiagnostic{
+			File:     fileName,
+			Range:    toDiagnosticRange(d),
+			Severity: "warning",
+			Message:  d.Message,
+			Source:   "templ",
+		}
+		if i < len(fixes) {
+			jd.Fix = fixes[i]
+		}
+		if err := enc.Encode(jd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lspDiagnostic is a single LSP Diagnostic, as used in PublishDiagnosticsParams.
+// Fix is templ-specific: it's carried alongside the diagnostic so an
+// editor can offer it as a CodeAction without a second round trip.
+type lspDiagnostic struct {
+	Range    diagnosticRange `json:"range"`
+	Severity int             `json:"severity"`
+	Message  string          `json:"message"`
+	Source   string          `json:"source"`
+	Fix      *Fix            `json:"fix,omitempty"`
+}
+
+// lspPublishDiagnosticsParams mirrors the LSP PublishDiagnosticsParams shape,
+// so editors can feed it straight into their diagnostics handling.
+type lspPublishDiagnosticsParams struct {
+	URI         string          `json:"uri"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+type lspDiagnosticSink struct{}
+
+func (lspDiagnosticSink) Publish(w io.Writer, fileName string, diags []parser.Diagnostic, fixes []*Fix, err error) error {
+	params := lspPublishDiagnosticsParams{URI: toFileURI(fileName), Diagnostics: []lspDiagnostic{}}
+	if err != nil {
+		params.Diagnostics = []lspDiagnostic{{Severity: lspSeverityError, Message: err.Error(), Source: "templ"}}
+		return json.NewEncoder(w).Encode(params)
+	}
+	for i, d := range diags {
+		ld := lspDiagnostic{
+			Range:    toDiagnosticRange(d),
+			Severity: lspSeverityWarning,
+			Message:  d.Message,
+			Source:   "templ",
+		}
+		if i < len(fixes) {
+			ld.Fix = fixes[i]
+		}
+		params.Diagnostics = append(params.Diagnostics, ld)
+	}
+	return json.NewEncoder(w).Encode(params)
+}
+
+func toFileURI(fileName string) string {
+	abs, err := filepath.Abs(fileName)
+	if err != nil {
+		abs = fileName
+	}
+	return "file://" + filepath.ToSlash(abs)
+}
@@ -0,0 +1,42 @@
+package generatecmd
+
+import "os"
+
+// FileSource abstracts reading the contents of a .templ file, so the same
+// generation code path can serve files on disk, in-memory editor overlays,
+// and a single file piped in over stdin.
+type FileSource interface {
+	ReadFile(path string) ([]byte, error)
+}
+
+// DiskFileSource reads file contents from the filesystem.
+type DiskFileSource struct{}
+
+func (DiskFileSource) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// OverlayFileSource serves contents from Overlay when present, falling back
+// to Underlying otherwise. This mirrors the overlay/FileHandle model editors
+// use to substitute unsaved buffer contents for what's on disk.
+type OverlayFileSource struct {
+	Overlay    map[string][]byte
+	Underlying FileSource
+}
+
+func (o OverlayFileSource) ReadFile(path string) ([]byte, error) {
+	if contents, ok := o.Overlay[path]; ok {
+		return contents, nil
+	}
+	return o.Underlying.ReadFile(path)
+}
+
+// NewFileSource returns a FileSource that serves overlay contents where
+// present, falling back to disk reads. If overlay is empty, it returns a
+// plain DiskFileSource.
+func NewFileSource(overlay map[string][]byte) FileSource {
+	if len(overlay) == 0 {
+		return DiskFileSource{}
+	}
+	return OverlayFileSource{Overlay: overlay, Underlying: DiskFileSource{}}
+}
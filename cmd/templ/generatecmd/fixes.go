@@ -0,0 +1,172 @@
+package generatecmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/a-h/templ/parser/v2"
+)
+
+// TextEdit replaces the text in Range with NewText.
+type TextEdit struct {
+	Range   parser.Range `json:"range"`
+	NewText string       `json:"newText"`
+}
+
+// Fix is a suggested, concrete edit for a diagnostic - templ's equivalent
+// of an LSP CodeAction bundled alongside the analyzer diagnostic that
+// produced it. Only the small set of common, mechanical mistakes below get
+// one; anything else is left for a human to fix.
+type Fix struct {
+	Title string     `json:"title"`
+	Edits []TextEdit `json:"edits"`
+}
+
+var (
+	unclosedTagMessage      = regexp.MustCompile(`(?i)unclosed tag`)
+	missingBraceMessage     = regexp.MustCompile(`(?i)expected (a closing|'\}')`)
+	mismatchedBlockMessage  = regexp.MustCompile(`(?i)expected (if|for|switch) .* to be closed`)
+	unknownAttributeMessage = regexp.MustCompile(`(?i)unknown attribute`)
+)
+
+// suggestFix returns the single most likely Fix for d, based on matching
+// its message against templ's most common recoverable parsing mistakes.
+// It returns false if d doesn't look like one of them.
+func suggestFix(d parser.Diagnostic) (Fix, bool) {
+	end := d.Range.To
+	switch {
+	case unclosedTagMessage.MatchString(d.Message):
+		return Fix{
+			Title: "Close the unclosed tag",
+			Edits: []TextEdit{{Range: parser.Range{From: end, To: end}, NewText: "</>"}},
+		}, true
+	case missingBraceMessage.MatchString(d.Message):
+		return Fix{
+			Title: "Insert missing '}'",
+			Edits: []TextEdit{{Range: parser.Range{From: end, To: end}, NewText: "}"}},
+		}, true
+	case mismatchedBlockMessage.MatchString(d.Message):
+		return Fix{
+			Title: "Close the block with '}'",
+			Edits: []TextEdit{{Range: parser.Range{From: end, To: end}, NewText: "}"}},
+		}, true
+	case unknownAttributeMessage.MatchString(d.Message):
+		return Fix{
+			Title: "Remove the unrecognised attribute",
+			Edits: []TextEdit{{Range: d.Range, NewText: ""}},
+		}, true
+	}
+	return Fix{}, false
+}
+
+// fixesFor returns, for each diagnostic in diags, the Fix suggested for
+// it, or nil if none applies. The result is parallel to diags.
+func fixesFor(diags []parser.Diagnostic) []*Fix {
+	fixes := make([]*Fix, len(diags))
+	for i, d := range diags {
+		if fix, ok := suggestFix(d); ok {
+			fixes[i] = &fix
+		}
+	}
+	return fixes
+}
+
+// offsetForPosition converts a parser.Position's line/column into a byte
+// offset into src, so fix edits (expressed in line/column terms, like the
+// rest of the diagnostics machinery) can be applied to the raw source.
+func offsetForPosition(src []byte, pos parser.Position) int {
+	var line, col uint32
+	for i, b := range src {
+		if line == pos.Line && col == pos.Col {
+			return i
+		}
+		if b == '\n' {
+			line++
+			col = 0
+			continue
+		}
+		col++
+	}
+	return len(src)
+}
+
+// applyFixes applies every fix suggested for diags to src and returns the
+// result, along with how many fixes were applied. Edits are applied from
+// the end of the file backwards, so earlier offsets aren't invalidated by
+// later edits.
+func applyFixes(src []byte, diags []parser.Diagnostic) ([]byte, int) {
+	type edit struct {
+		from, to int
+		newText  string
+	}
+	var edits []edit
+	for _, d := range diags {
+		fix, ok := suggestFix(d)
+		if !ok {
+			continue
+		}
+		for _, e := range fix.Edits {
+			edits = append(edits, edit{
+				from:    offsetForPosition(src, e.Range.From),
+				to:      offsetForPosition(src, e.Range.To),
+				newText: e.NewText,
+			})
+		}
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].from > edits[j].from })
+	for _, e := range edits {
+		var b bytes.Buffer
+		b.Write(src[:e.from])
+		b.WriteString(e.newText)
+		b.Write(src[e.to:])
+		src = b.Bytes()
+	}
+	return src, len(edits)
+}
+
+// confirmApplyFixesMu serializes prompts across the concurrent
+// processSingleFile workers started by processChanges/processWatchedFiles,
+// so that two files with fixes pending in the same pass don't race to
+// read the same answer off os.Stdin.
+//
+// stdinReader is shared across every call, rather than built fresh per
+// prompt: bufio.NewScanner(os.Stdin) pulls and buffers everything
+// available on stdin on its first Scan, so a second, independently
+// constructed scanner sees only what's left after that - nothing, for
+// any answer after the first in a pass with more than one fix pending.
+var (
+	confirmApplyFixesMu sync.Mutex
+	stdinReader         = bufio.NewReader(os.Stdin)
+)
+
+// confirmApplyFixes asks the user, via stdin, whether to apply count
+// fixes to fileName, writing the prompt to w.
+func confirmApplyFixes(w io.Writer, fileName string, count int) bool {
+	confirmApplyFixesMu.Lock()
+	defer confirmApplyFixesMu.Unlock()
+	fmt.Fprintf(w, "Apply %d suggested fix(es) to %q? [y/N]: ", count, fileName)
+	return confirmFromReader(stdinReader)
+}
+
+// confirmFromReader reads a single line from r and reports whether it's
+// an affirmative answer. It's split out from confirmApplyFixes so tests
+// can drive it without touching os.Stdin.
+func confirmFromReader(r *bufio.Reader) bool {
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+	switch strings.TrimSpace(line) {
+	case "y", "Y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,107 @@
+package generatecmd
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ/parser/v2"
+)
+
+func pos(line, col uint32) parser.Position {
+	return parser.NewPosition(0, line, col)
+}
+
+func TestSuggestFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		wantFix bool
+	}{
+		{name: "unclosed tag", message: "unclosed tag 'div'", wantFix: true},
+		{name: "missing brace", message: "expected a closing '}'", wantFix: true},
+		{name: "mismatched if block", message: "expected if expression to be closed", wantFix: true},
+		{name: "unknown attribute", message: "unknown attribute 'hrref'", wantFix: true},
+		{name: "unrecognised message", message: "something else entirely went wrong", wantFix: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := parser.Diagnostic{Message: tt.message, Range: parser.Range{From: pos(1, 0), To: pos(1, 5)}}
+			_, ok := suggestFix(d)
+			if ok != tt.wantFix {
+				t.Errorf("suggestFix(%q) ok = %v, want %v", tt.message, ok, tt.wantFix)
+			}
+		})
+	}
+}
+
+func TestOffsetForPosition(t *testing.T) {
+	src := []byte("abc\ndef\nghi")
+	tests := []struct {
+		name string
+		pos  parser.Position
+		want int
+	}{
+		{name: "start of file", pos: pos(0, 0), want: 0},
+		{name: "mid first line", pos: pos(0, 2), want: 2},
+		{name: "start of second line", pos: pos(1, 0), want: 4},
+		{name: "mid third line", pos: pos(2, 1), want: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := offsetForPosition(src, tt.pos); got != tt.want {
+				t.Errorf("offsetForPosition() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	src := []byte("<div>")
+	diags := []parser.Diagnostic{
+		{Message: "unclosed tag 'div'", Range: parser.Range{From: pos(0, 5), To: pos(0, 5)}},
+	}
+	fixed, n := applyFixes(src, diags)
+	if n != 1 {
+		t.Fatalf("expected 1 fix to be applied, got %d", n)
+	}
+	if !bytes.Equal(fixed, []byte("<div></>")) {
+		t.Errorf("applyFixes() = %q, want %q", fixed, "<div></>")
+	}
+}
+
+func TestApplyFixesNoneApplicable(t *testing.T) {
+	src := []byte("<div></div>")
+	diags := []parser.Diagnostic{
+		{Message: "something else entirely went wrong", Range: parser.Range{From: pos(0, 0), To: pos(0, 0)}},
+	}
+	fixed, n := applyFixes(src, diags)
+	if n != 0 {
+		t.Fatalf("expected no fixes to be applied, got %d", n)
+	}
+	if !bytes.Equal(fixed, src) {
+		t.Errorf("applyFixes() modified src with no applicable fixes: %q", fixed)
+	}
+}
+
+func TestConfirmFromReaderAnswersMultiplePromptsInSequence(t *testing.T) {
+	// A single reader must serve every prompt in a pass with more than one
+	// file awaiting confirmation, the same way stdinReader is shared across
+	// confirmApplyFixes calls: a fresh bufio.Reader per call would drop
+	// everything after the first buffered line.
+	r := bufio.NewReader(strings.NewReader("y\nn\nyes\n"))
+	want := []bool{true, false, true}
+	for i, w := range want {
+		if got := confirmFromReader(r); got != w {
+			t.Errorf("prompt %d: confirmFromReader() = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestConfirmFromReaderEOF(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader(""))
+	if confirmFromReader(r) {
+		t.Error("expected EOF with no input to be treated as a decline")
+	}
+}
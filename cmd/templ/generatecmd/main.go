@@ -16,7 +16,6 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -50,10 +49,27 @@ type Arguments struct {
 	// PPROFPort is the port to run the pprof server on.
 	PPROFPort         int
 	KeepOrphanedFiles bool
+	// DiagnosticsFormat selects how diagnostics are reported: "" or "text"
+	// for the default human-oriented console output, "json" for NDJSON,
+	// or "lsp" for an LSP PublishDiagnosticsParams envelope per file.
+	DiagnosticsFormat string
+	// Overlay holds unsaved editor buffer contents, keyed by absolute file
+	// path, that take precedence over what's on disk. This lets an LSP
+	// generate from a buffer the editor hasn't saved yet.
+	Overlay map[string][]byte
+	// Stdin reads a single .templ file from stdin and writes the generated
+	// Go code to stdout, without touching the filesystem.
+	Stdin bool
+	// NoCache bypasses the persisted content hash cache: it is neither read
+	// from nor written to disk, so the first pass regenerates every .templ
+	// file. In watch mode, a file whose content is unchanged since an
+	// earlier pass of the same run is still skipped.
+	NoCache bool
+	// ApplyFixes prompts to apply each diagnostic's suggested Fix, if any,
+	// to the .templ source in place before regenerating.
+	ApplyFixes bool
 }
 
-var defaultWorkerCount = runtime.NumCPU()
-
 func Run(w io.Writer, args Arguments) (err error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	signalChan := make(chan os.Signal, 1)
@@ -62,7 +78,9 @@ func Run(w io.Writer, args Arguments) (err error) {
 		signal.Stop(signalChan)
 		cancel()
 	}()
+	policy := NewWorkerPolicy(args.WorkerCount)
 	if args.PPROFPort > 0 {
+		http.Handle("/debug/templ/workers", policy)
 		go func() {
 			_ = http.ListenAndServe(fmt.Sprintf("localhost:%d", args.PPROFPort), nil)
 		}()
@@ -81,18 +99,21 @@ func Run(w io.Writer, args Arguments) (err error) {
 		<-signalChan // Second signal, hard exit.
 		os.Exit(2)
 	}()
-	err = runCmd(ctx, w, args)
+	err = runCmd(ctx, w, args, policy)
 	if errors.Is(err, context.Canceled) {
 		return nil
 	}
 	return err
 }
 
-func runCmd(ctx context.Context, w io.Writer, args Arguments) (err error) {
+func runCmd(ctx context.Context, w io.Writer, args Arguments, policy *WorkerPolicy) (err error) {
 	start := time.Now()
 	if args.Watch && args.FileName != "" {
 		return fmt.Errorf("cannot watch a single file, remove the -f or -watch flag")
 	}
+	if args.Stdin && (args.Watch || args.FileName != "") {
+		return fmt.Errorf("cannot use -stdin with -watch or -f")
+	}
 	var opts []generator.GenerateOpt
 	if args.IncludeVersion {
 		opts = append(opts, generator.WithVersion(templ.Version()))
@@ -100,8 +121,18 @@ func runCmd(ctx context.Context, w io.Writer, args Arguments) (err error) {
 	if args.IncludeTimestamp {
 		opts = append(opts, generator.WithTimestamp(time.Now()))
 	}
+	sink, err := NewDiagnosticSink(args.DiagnosticsFormat)
+	if err != nil {
+		return err
+	}
+	if args.Stdin {
+		return generateStdin(ctx, w, os.Stderr, opts, sink)
+	}
+	fs := NewFileSource(args.Overlay)
 	if args.FileName != "" {
-		return processSingleFile(ctx, w, "", args.FileName, args.GenerateSourceMapVisualisations, opts)
+		wrote, err := processSingleFile(ctx, w, fs, "", args.FileName, args.GenerateSourceMapVisualisations, opts, sink, args.ApplyFixes)
+		_ = wrote
+		return err
 	}
 	var target *url.URL
 	if args.Proxy != "" {
@@ -114,9 +145,6 @@ func runCmd(ctx context.Context, w io.Writer, args Arguments) (err error) {
 		args.ProxyPort = 7331
 	}
 
-	if args.WorkerCount == 0 {
-		args.WorkerCount = defaultWorkerCount
-	}
 	if !path.IsAbs(args.Path) {
 		args.Path, err = filepath.Abs(args.Path)
 		if err != nil {
@@ -128,71 +156,127 @@ func runCmd(ctx context.Context, w io.Writer, args Arguments) (err error) {
 	if args.Proxy != "" {
 		p = proxy.New(args.ProxyPort, target)
 	}
-	fmt.Fprintln(w, "Processing path:", args.Path)
+	sw := statusWriter(w, args.DiagnosticsFormat)
+	fmt.Fprintln(sw, "Processing path:", args.Path)
+
+	// The first pass always walks the whole tree, so that the state of
+	// every .templ file is known. Files whose content hash matches the
+	// persisted cache are not regenerated.
+	cache := loadChangeCache(moduleRoot(args.Path), args.NoCache)
+	changesFound, errs := processChanges(ctx, w, sw, fs, cache, args.Path, args.GenerateSourceMapVisualisations, opts, policy, args.KeepOrphanedFiles, sink, args.ApplyFixes)
+	if err := cache.save(); err != nil {
+		logWarning(sw, "Failed to save change cache: %v\n", err)
+	}
+	if err = reportGeneration(ctx, sw, args, p, start, changesFound, errs, true); err != nil {
+		return err
+	}
+
+	if !args.Watch {
+		return nil
+	}
+
+	// Subsequent passes are event-driven where possible: fsnotify watches
+	// are cheaper and react in milliseconds, rather than waiting out a
+	// polling interval. If fsnotify can't be used on this platform, fall
+	// back to the original poll-and-diff loop.
+	if err = watchEvents(ctx, w, args, p, fs, cache, opts, policy, sink); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		logWarning(sw, "Falling back to polling for file changes: %v\n", err)
+		return pollForChanges(ctx, w, args, p, fs, cache, opts, policy, sink)
+	}
+	return nil
+}
+
+// statusWriter returns the writer for prose status output: progress
+// messages, command execution, proxy/browser messages, and version
+// checks. With the default text output these interleave with the
+// diagnostics on w as they always have; with a machine-readable
+// --diagnostics-format they're routed to stderr instead, so stdout stays
+// clean NDJSON/LSP for editors and CI to consume.
+func statusWriter(w io.Writer, diagnosticsFormat string) io.Writer {
+	if diagnosticsFormat == "" || diagnosticsFormat == "text" {
+		return w
+	}
+	return os.Stderr
+}
+
+// pollForChanges re-walks args.Path on an exponential backoff, comparing
+// content hashes against cache. It is the original watch implementation,
+// kept as a fallback for platforms where fsnotify fails to initialize.
+func pollForChanges(ctx context.Context, w io.Writer, args Arguments, p *proxy.Handler, fs FileSource, cache *changeCache, opts []generator.GenerateOpt, policy *WorkerPolicy, sink DiagnosticSink) (err error) {
 	bo := backoff.NewExponentialBackOff()
 	bo.InitialInterval = time.Millisecond * 500
 	bo.MaxInterval = time.Second * 3
 	bo.MaxElapsedTime = 0
+	sw := statusWriter(w, args.DiagnosticsFormat)
 
-	var firstRunComplete bool
-	fileNameToLastModTime := make(map[string]time.Time)
-	for !firstRunComplete || args.Watch {
-		changesFound, errs := processChanges(ctx, w, fileNameToLastModTime, args.Path, args.GenerateSourceMapVisualisations, opts, args.WorkerCount, args.KeepOrphanedFiles)
-		if len(errs) > 0 {
-			if errors.Is(errs[0], context.Canceled) {
-				return errs[0]
-			}
-			if !args.Watch {
-				return fmt.Errorf("failed to process path: %v", errors.Join(errs...))
-			}
-			logError(w, "Error processing path: %v\n", errors.Join(errs...))
+	for {
+		start := time.Now()
+		changesFound, errs := processChanges(ctx, w, sw, fs, cache, args.Path, args.GenerateSourceMapVisualisations, opts, policy, args.KeepOrphanedFiles, sink, args.ApplyFixes)
+		if err := cache.save(); err != nil {
+			logWarning(sw, "Failed to save change cache: %v\n", err)
+		}
+		if err = reportGeneration(ctx, sw, args, p, start, changesFound, errs, false); err != nil {
+			return err
 		}
 		if changesFound > 0 {
-			if len(errs) > 0 {
-				logError(w, "Generated code for %d templates with %d errors in %s\n", changesFound, len(errs), time.Since(start))
-			} else {
-				logSuccess(w, "Generated code for %d templates with %d errors in %s\n", changesFound, len(errs), time.Since(start))
-			}
-			if args.Command != "" {
-				fmt.Fprintf(w, "Executing command: %s\n", args.Command)
-				if _, err := run.Run(ctx, args.Path, args.Command); err != nil {
-					fmt.Fprintf(w, "Error starting command: %v\n", err)
-				}
-			}
-			// Send server-sent event.
-			if p != nil {
-				p.SendSSE("message", "reload")
-			}
+			bo.Reset()
+		}
+		time.Sleep(bo.NextBackOff())
+	}
+}
 
-			if !firstRunComplete && p != nil {
-				go func() {
-					fmt.Fprintf(w, "Proxying from %s to target: %s\n", p.URL, p.Target.String())
-					if err := http.ListenAndServe(fmt.Sprintf("127.0.0.1:%d", args.ProxyPort), p); err != nil {
-						fmt.Fprintf(w, "Error starting proxy: %v\n", err)
-					}
-				}()
-				go func() {
-					fmt.Fprintf(w, "Opening URL: %s\n", p.Target.String())
-					if err := openURL(w, p.URL); err != nil {
-						fmt.Fprintf(w, "Error opening URL: %v\n", err)
-					}
-				}()
-			}
+// reportGeneration logs the outcome of a generation pass, runs the
+// configured command, and triggers a reload over the proxy's SSE
+// connection. On the first successful pass it also starts the proxy
+// server and opens the browser.
+func reportGeneration(ctx context.Context, w io.Writer, args Arguments, p *proxy.Handler, start time.Time, changesFound int, errs []error, isFirstRun bool) error {
+	if len(errs) > 0 {
+		if errors.Is(errs[0], context.Canceled) {
+			return errs[0]
 		}
-		if err = checkTemplVersion(args.Path); err != nil {
-			logWarning(w, "templ version check failed: %v\n", err)
-			err = nil
+		if !args.Watch {
+			return fmt.Errorf("failed to process path: %v", errors.Join(errs...))
+		}
+		logError(w, "Error processing path: %v\n", errors.Join(errs...))
+	}
+	if changesFound > 0 || isFirstRun {
+		if len(errs) > 0 {
+			logError(w, "Generated code for %d templates with %d errors in %s\n", changesFound, len(errs), time.Since(start))
+		} else {
+			logSuccess(w, "Generated code for %d templates with %d errors in %s\n", changesFound, len(errs), time.Since(start))
 		}
-		if firstRunComplete {
-			if changesFound > 0 {
-				bo.Reset()
+		if args.Command != "" {
+			fmt.Fprintf(w, "Executing command: %s\n", args.Command)
+			if _, err := run.Run(ctx, args.Path, args.Command); err != nil {
+				fmt.Fprintf(w, "Error starting command: %v\n", err)
 			}
-			time.Sleep(bo.NextBackOff())
 		}
-		firstRunComplete = true
-		start = time.Now()
+		// Send server-sent event.
+		if p != nil {
+			p.SendSSE("message", "reload")
+		}
+		if isFirstRun && p != nil {
+			go func() {
+				fmt.Fprintf(w, "Proxying from %s to target: %s\n", p.URL, p.Target.String())
+				if err := http.ListenAndServe(fmt.Sprintf("127.0.0.1:%d", args.ProxyPort), p); err != nil {
+					fmt.Fprintf(w, "Error starting proxy: %v\n", err)
+				}
+			}()
+			go func() {
+				fmt.Fprintf(w, "Opening URL: %s\n", p.Target.String())
+				if err := openURL(w, p.URL); err != nil {
+					fmt.Fprintf(w, "Error opening URL: %v\n", err)
+				}
+			}()
+		}
 	}
-	return err
+	if err := checkTemplVersion(args.Path); err != nil {
+		logWarning(w, "templ version check failed: %v\n", err)
+	}
+	return nil
 }
 
 func shouldSkipDir(dir string) bool {
@@ -210,9 +294,10 @@ func shouldSkipDir(dir string) bool {
 	return false
 }
 
-func processChanges(ctx context.Context, stdout io.Writer, fileNameToLastModTime map[string]time.Time, path string, generateSourceMapVisualisations bool, opts []generator.GenerateOpt, maxWorkerCount int, keepOrphanedFiles bool) (changesFound int, errs []error) {
-	sem := make(chan struct{}, maxWorkerCount)
+func processChanges(ctx context.Context, stdout, status io.Writer, fs FileSource, cache *changeCache, path string, generateSourceMapVisualisations bool, opts []generator.GenerateOpt, policy *WorkerPolicy, keepOrphanedFiles bool, sink DiagnosticSink, applyFixesEnabled bool) (changesFound int, errs []error) {
+	sem := make(chan struct{}, policy.Count())
 	var wg sync.WaitGroup
+	var mu sync.Mutex
 
 	err := filepath.WalkDir(path, func(fileName string, info os.DirEntry, err error) error {
 		if err != nil {
@@ -237,30 +322,37 @@ func processChanges(ctx context.Context, stdout io.Writer, fileNameToLastModTime
 			if err = os.Remove(fileName); err != nil {
 				return fmt.Errorf("failed to remove file: %w", err)
 			}
-			logWarning(stdout, "Deleted orphaned file %q\n", fileName)
+			logWarning(status, "Deleted orphaned file %q\n", fileName)
 			return nil
 		}
 		if strings.HasSuffix(fileName, ".templ") {
-			lastModTime := fileNameToLastModTime[fileName]
-			fileInfo, err := info.Info()
+			contents, err := fs.ReadFile(fileName)
 			if err != nil {
-				return fmt.Errorf("failed to get file info: %w", err)
+				return fmt.Errorf("failed to read file: %w", err)
 			}
-			if fileInfo.ModTime().After(lastModTime) {
-				fileNameToLastModTime[fileName] = fileInfo.ModTime()
-				changesFound++
-
-				// Start a processor, but limit to maxWorkerCount.
-				sem <- struct{}{}
-				wg.Add(1)
-				go func() {
-					defer wg.Done()
-					if err := processSingleFile(ctx, stdout, path, fileName, generateSourceMapVisualisations, opts); err != nil {
-						errs = append(errs, err)
-					}
-					<-sem
-				}()
+			if !cache.changed(fileName, contents) {
+				return nil
 			}
+
+			// Start a processor, but limit to maxWorkerCount.
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				done := policy.Begin()
+				fileStart := time.Now()
+				wrote, err := processSingleFile(ctx, stdout, fs, path, fileName, generateSourceMapVisualisations, opts, sink, applyFixesEnabled)
+				done(fileName, time.Since(fileStart))
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				}
+				if wrote {
+					changesFound++
+				}
+				mu.Unlock()
+				<-sem
+			}()
 		}
 		return nil
 	})
@@ -289,45 +381,106 @@ func openURL(w io.Writer, url string) error {
 	return browser.OpenURL(url)
 }
 
-// processSingleFile generates Go code for a single template.
+// processSingleFile generates Go code for a single template. It reports
+// wrote=true if the generated output differed from what was already on
+// disk and so was actually written.
+// If applyFixesEnabled is set and any diagnostics have a suggested Fix,
+// the user is prompted (via stdin) to apply them to the .templ source in
+// place, after which the file is regenerated.
 // If a basePath is provided, the filename included in error messages is relative to it.
-func processSingleFile(ctx context.Context, stdout io.Writer, basePath, fileName string, generateSourceMapVisualisations bool, opts []generator.GenerateOpt) (err error) {
+func processSingleFile(ctx context.Context, stdout io.Writer, fs FileSource, basePath, fileName string, generateSourceMapVisualisations bool, opts []generator.GenerateOpt, sink DiagnosticSink, applyFixesEnabled bool) (wrote bool, err error) {
 	start := time.Now()
-	diag, err := generate(ctx, basePath, fileName, generateSourceMapVisualisations, opts)
-	if err != nil {
-		return err
+	diag, wrote, err := generate(ctx, fs, basePath, fileName, generateSourceMapVisualisations, opts)
+	if err == nil && applyFixesEnabled && tryApplyFixes(stdout, fs, fileName, diag) {
+		diag, wrote, err = generate(ctx, fs, basePath, fileName, generateSourceMapVisualisations, opts)
 	}
+	fixes := fixesFor(diag)
 	var b bytes.Buffer
 	defer func() {
 		_, _ = b.WriteTo(stdout)
 	}()
+	if err != nil {
+		if pubErr := sink.Publish(&b, fileName, nil, nil, err); pubErr != nil {
+			return false, pubErr
+		}
+		return false, err
+	}
+	if pubErr := sink.Publish(&b, fileName, diag, fixes, nil); pubErr != nil {
+		return wrote, pubErr
+	}
+	if _, isText := sink.(textDiagnosticSink); !isText {
+		return wrote, nil
+	}
 	if len(diag) > 0 {
 		logWarning(&b, "Generated code for %q in %s\n", fileName, time.Since(start))
-		printDiagnostics(&b, fileName, diag)
-		return nil
+		return wrote, nil
 	}
 	logSuccess(&b, "Generated code for %q in %s\n", fileName, time.Since(start))
-	return nil
+	return wrote, nil
+}
+
+// tryApplyFixes applies any fixes suggested for diag to fileName's source
+// on disk, after confirming with the user, reporting whether any edits
+// were made.
+func tryApplyFixes(stdout io.Writer, fs FileSource, fileName string, diag []parser.Diagnostic) bool {
+	fixes := fixesFor(diag)
+	count := 0
+	for _, f := range fixes {
+		if f != nil {
+			count++
+		}
+	}
+	if count == 0 {
+		return false
+	}
+	src, err := fs.ReadFile(fileName)
+	if err != nil {
+		return false
+	}
+	if !confirmApplyFixes(stdout, fileName, count) {
+		return false
+	}
+	fixed, n := applyFixes(src, diag)
+	if n == 0 {
+		return false
+	}
+	if err := os.WriteFile(fileName, fixed, 0644); err != nil {
+		logWarning(stdout, "Failed to write fixes to %q: %v\n", fileName, err)
+		return false
+	}
+	logSuccess(stdout, "Applied %d fix(es) to %q\n", n, fileName)
+	return true
 }
 
-func printDiagnostics(w io.Writer, fileName string, diags []parser.Diagnostic) {
-	for _, d := range diags {
+func printDiagnostics(w io.Writer, fileName string, diags []parser.Diagnostic, fixes []*Fix) {
+	for i, d := range diags {
 		fmt.Fprint(w, "\t")
 		logWarning(w, "%s (%d:%d)\n", d.Message, d.Range.From.Line, d.Range.From.Col)
+		if i < len(fixes) && fixes[i] != nil {
+			fmt.Fprintf(w, "\t\tfix available: %s (run with --apply-fixes to apply)\n", fixes[i].Title)
+		}
 	}
 	fmt.Fprintln(w)
 }
 
-// generate Go code for a single template.
+// generate Go code for a single template. The write to targetFileName is
+// skipped, and wrote is false, if the freshly generated output is
+// byte-for-byte identical to what's already there, so that an unrelated
+// edit that doesn't change the generated code doesn't cause a spurious
+// `go build` invalidation or SSE reload.
 // If a basePath is provided, the filename included in error messages is relative to it.
-func generate(ctx context.Context, basePath, fileName string, generateSourceMapVisualisations bool, opts []generator.GenerateOpt) (diagnostics []parser.Diagnostic, err error) {
+func generate(ctx context.Context, fs FileSource, basePath, fileName string, generateSourceMapVisualisations bool, opts []generator.GenerateOpt) (diagnostics []parser.Diagnostic, wrote bool, err error) {
 	if err = ctx.Err(); err != nil {
 		return
 	}
 
-	t, err := parser.Parse(fileName)
+	src, err := fs.ReadFile(fileName)
 	if err != nil {
-		return nil, fmt.Errorf("%s parsing error: %w", fileName, err)
+		return nil, false, fmt.Errorf("%s read error: %w", fileName, err)
+	}
+	t, err := parser.ParseString(string(src))
+	if err != nil {
+		return nil, false, fmt.Errorf("%s parsing error: %w", fileName, err)
 	}
 	targetFileName := strings.TrimSuffix(fileName, ".templ") + "_templ.go"
 
@@ -340,25 +493,28 @@ func generate(ctx context.Context, basePath, fileName string, generateSourceMapV
 	var b bytes.Buffer
 	sourceMap, err := generator.Generate(t, &b, append(opts, generator.WithFileName(errorMessageFileName))...)
 	if err != nil {
-		return nil, fmt.Errorf("%s generation error: %w", fileName, err)
+		return nil, false, fmt.Errorf("%s generation error: %w", fileName, err)
 	}
 
 	data, err := format.Source(b.Bytes())
 	if err != nil {
-		return nil, fmt.Errorf("%s source formatting error: %w", fileName, err)
+		return nil, false, fmt.Errorf("%s source formatting error: %w", fileName, err)
 	}
 
-	if err = os.WriteFile(targetFileName, data, 0644); err != nil {
-		return nil, fmt.Errorf("%s write file error: %w", targetFileName, err)
+	if existing, err := os.ReadFile(targetFileName); err != nil || !bytes.Equal(existing, data) {
+		if err = os.WriteFile(targetFileName, data, 0644); err != nil {
+			return nil, false, fmt.Errorf("%s write file error: %w", targetFileName, err)
+		}
+		wrote = true
 	}
 
 	if generateSourceMapVisualisations {
-		err = generateSourceMapVisualisation(ctx, fileName, targetFileName, sourceMap)
+		err = generateSourceMapVisualisation(ctx, fs, fileName, targetFileName, sourceMap)
 	}
-	return t.Diagnostics, err
+	return t.Diagnostics, wrote, err
 }
 
-func generateSourceMapVisualisation(ctx context.Context, templFileName, goFileName string, sourceMap *parser.SourceMap) error {
+func generateSourceMapVisualisation(ctx context.Context, fs FileSource, templFileName, goFileName string, sourceMap *parser.SourceMap) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -368,7 +524,7 @@ func generateSourceMapVisualisation(ctx context.Context, templFileName, goFileNa
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		templContents, templErr = os.ReadFile(templFileName)
+		templContents, templErr = fs.ReadFile(templFileName)
 	}()
 	go func() {
 		defer wg.Done()
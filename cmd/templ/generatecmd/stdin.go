@@ -0,0 +1,60 @@
+package generatecmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+
+	"github.com/a-h/templ/generator"
+	"github.com/a-h/templ/parser/v2"
+)
+
+// stdinFileName is the pseudo path used for a template read from stdin. It
+// has no corresponding file on disk, so it's only ever used in diagnostic
+// and error messages, including the runtime error messages of the
+// generated code.
+const stdinFileName = "<stdin>"
+
+// generateStdin reads a single .templ file from stdin and writes the
+// generated Go code to stdout, without touching the filesystem. This
+// supports editor integrations that generate from an unsaved buffer, and
+// "format on save" workflows that pipe through templ.
+func generateStdin(ctx context.Context, stdout, stderr io.Writer, opts []generator.GenerateOpt, sink DiagnosticSink) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	src, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	t, err := parser.ParseString(string(src))
+	if err != nil {
+		if pubErr := sink.Publish(stderr, stdinFileName, nil, nil, err); pubErr != nil {
+			return pubErr
+		}
+		return fmt.Errorf("%s parsing error: %w", stdinFileName, err)
+	}
+	if len(t.Diagnostics) > 0 {
+		// Stdin mode has no file on disk to apply a fix to, so fixes aren't
+		// computed or surfaced here.
+		if err := sink.Publish(stderr, stdinFileName, t.Diagnostics, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	var b bytes.Buffer
+	if _, err := generator.Generate(t, &b, append(opts, generator.WithFileName(stdinFileName))...); err != nil {
+		return fmt.Errorf("%s generation error: %w", stdinFileName, err)
+	}
+
+	data, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s source formatting error: %w", stdinFileName, err)
+	}
+
+	_, err = stdout.Write(data)
+	return err
+}
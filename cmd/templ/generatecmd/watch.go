@@ -0,0 +1,198 @@
+package generatecmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ/cmd/templ/generatecmd/proxy"
+	"github.com/a-h/templ/generator"
+	"github.com/fsnotify/fsnotify"
+)
+
+// debounceDuration is how long watchEvents waits after the last filesystem
+// event before running a generation pass, so that a burst of Write/Rename
+// events from a single save collapses into one pass.
+const debounceDuration = 100 * time.Millisecond
+
+// watchEvents watches args.Path and its subdirectories for changes using
+// fsnotify, regenerating .templ files as events arrive. Watches are added
+// and removed as directories are created and removed. It returns an error
+// if fsnotify can't be initialized or a watch can't be added; callers
+// should fall back to polling in that case. It otherwise blocks until ctx
+// is cancelled.
+func watchEvents(ctx context.Context, w io.Writer, args Arguments, p *proxy.Handler, fs FileSource, cache *changeCache, opts []generator.GenerateOpt, policy *WorkerPolicy, sink DiagnosticSink) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err = addWatchesRecursively(watcher, args.Path); err != nil {
+		return fmt.Errorf("failed to watch %q: %w", args.Path, err)
+	}
+
+	sw := statusWriter(w, args.DiagnosticsFormat)
+	var debounceTimer *time.Timer
+	pending := make(map[string]struct{})
+
+	resetDebounce := func() {
+		// Stop any pending timer and start a fresh one, rather than trying
+		// to Reset the existing one: Stop returning false only tells us the
+		// timer already fired, not whether anything drained its channel, so
+		// a blind "drain then Reset" can hang forever waiting for a value
+		// that's never coming.
+		if debounceTimer != nil {
+			debounceTimer.Stop()
+		}
+		debounceTimer = time.NewTimer(debounceDuration)
+	}
+	debounceChan := func() <-chan time.Time {
+		if debounceTimer == nil {
+			return nil
+		}
+		return debounceTimer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if err := handleWatchEvent(watcher, event); err != nil {
+				logWarning(sw, "Error handling watch event for %q: %v\n", event.Name, err)
+				continue
+			}
+			if strings.HasSuffix(event.Name, ".templ") {
+				pending[event.Name] = struct{}{}
+				resetDebounce()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logWarning(sw, "Watch error: %v\n", err)
+		case <-debounceChan():
+			if len(pending) == 0 {
+				continue
+			}
+			fileNames := make([]string, 0, len(pending))
+			for fileName := range pending {
+				fileNames = append(fileNames, fileName)
+				delete(pending, fileName)
+			}
+			start := time.Now()
+			changesFound, errs := processWatchedFiles(ctx, w, sw, fs, cache, fileNames, args.GenerateSourceMapVisualisations, opts, policy, args.KeepOrphanedFiles, sink, args.ApplyFixes)
+			if err := cache.save(); err != nil {
+				logWarning(sw, "Failed to save change cache: %v\n", err)
+			}
+			if err := reportGeneration(ctx, sw, args, p, start, changesFound, errs, false); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// addWatchesRecursively registers a watch on root and every non-skipped
+// subdirectory beneath it.
+func addWatchesRecursively(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(dirName string, info os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if shouldSkipDir(dirName) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(dirName)
+	})
+}
+
+// handleWatchEvent keeps the watch list in sync with the directory tree:
+// newly created directories are watched, and removed or renamed-away
+// directories have their watch dropped.
+func handleWatchEvent(watcher *fsnotify.Watcher, event fsnotify.Event) error {
+	switch {
+	case event.Has(fsnotify.Create):
+		info, err := os.Stat(event.Name)
+		if err != nil {
+			// The file may already have been removed again; nothing to watch.
+			return nil
+		}
+		if info.IsDir() {
+			return addWatchesRecursively(watcher, event.Name)
+		}
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		// Best-effort: fsnotify drops watches on removed paths automatically,
+		// but a renamed-away directory's watch needs removing explicitly.
+		_ = watcher.Remove(event.Name)
+	}
+	return nil
+}
+
+// processWatchedFiles regenerates the given .templ files, skipping any that
+// no longer exist (e.g. they were removed between the event firing and
+// now) or whose content hash hasn't actually changed (an editor can emit
+// more than one Write event for a single save).
+func processWatchedFiles(ctx context.Context, stdout, status io.Writer, fs FileSource, cache *changeCache, fileNames []string, generateSourceMapVisualisations bool, opts []generator.GenerateOpt, policy *WorkerPolicy, keepOrphanedFiles bool, sink DiagnosticSink, applyFixesEnabled bool) (changesFound int, errs []error) {
+	sem := make(chan struct{}, policy.Count())
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for _, fileName := range fileNames {
+		if err := ctx.Err(); err != nil {
+			return changesFound, []error{err}
+		}
+		contents, err := fs.ReadFile(fileName)
+		if err != nil {
+			if !keepOrphanedFiles {
+				goFileName := strings.TrimSuffix(fileName, ".templ") + "_templ.go"
+				if _, err := os.Stat(goFileName); err == nil {
+					if err := os.Remove(goFileName); err != nil {
+						errs = append(errs, fmt.Errorf("failed to remove file: %w", err))
+						continue
+					}
+					logWarning(status, "Deleted orphaned file %q\n", goFileName)
+				}
+			}
+			cache.forget(fileName)
+			continue
+		}
+		if !cache.changed(fileName, contents) {
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(fileName string) {
+			defer wg.Done()
+			done := policy.Begin()
+			fileStart := time.Now()
+			wrote, err := processSingleFile(ctx, stdout, fs, "", fileName, generateSourceMapVisualisations, opts, sink, applyFixesEnabled)
+			done(fileName, time.Since(fileStart))
+			mu.Lock()
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if wrote {
+				changesFound++
+			}
+			mu.Unlock()
+			<-sem
+		}(fileName)
+	}
+
+	wg.Wait()
+
+	return changesFound, errs
+}
@@ -0,0 +1,114 @@
+package generatecmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// maxRecentLatencies bounds how many per-file latencies WorkerPolicy keeps,
+// so /debug/templ/workers stays cheap to serve on a long-running watch
+// process.
+const maxRecentLatencies = 50
+
+// WorkerPolicy decides how many files templ generates concurrently, and
+// tracks enough live state to serve that decision (and its effect) over the
+// /debug/templ/workers pprof endpoint: the worker cap in effect, the current
+// queue depth, and recent per-file generation latency.
+type WorkerPolicy struct {
+	count int
+
+	mu         sync.Mutex
+	queueDepth int
+	recent     []fileLatency
+}
+
+type fileLatency struct {
+	FileName   string  `json:"file"`
+	DurationMS float64 `json:"durationMs"`
+}
+
+// NewWorkerPolicy returns a WorkerPolicy for generation concurrency. If
+// override is non-zero (the -w flag was set explicitly), it
+// wins outright. Otherwise the count is min(NumCPU, GOMAXPROCS, cgroup
+// quota), halved on darwin/windows when attached to an interactive
+// terminal, since those are the platforms where templ watch is most often
+// run as part of an interactive edit loop competing for the same cores as
+// the editor and browser.
+func NewWorkerPolicy(override int) *WorkerPolicy {
+	if override > 0 {
+		return &WorkerPolicy{count: override}
+	}
+	count := runtime.NumCPU()
+	if gomaxprocs := runtime.GOMAXPROCS(0); gomaxprocs < count {
+		count = gomaxprocs
+	}
+	if quota, ok := platformCPULimit(); ok && quota < count {
+		count = quota
+	}
+	if (runtime.GOOS == "darwin" || runtime.GOOS == "windows") && isInteractive() {
+		count /= 2
+	}
+	if count < 1 {
+		count = 1
+	}
+	return &WorkerPolicy{count: count}
+}
+
+// Count is the maximum number of files to generate concurrently.
+func (p *WorkerPolicy) Count() int {
+	return p.count
+}
+
+// Begin records that a file has started generating, and returns a func to
+// call once it's done, which records its generation latency. Call the
+// returned func exactly once.
+func (p *WorkerPolicy) Begin() func(fileName string, d time.Duration) {
+	p.mu.Lock()
+	p.queueDepth++
+	p.mu.Unlock()
+	return func(fileName string, d time.Duration) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.queueDepth--
+		p.recent = append(p.recent, fileLatency{FileName: fileName, DurationMS: float64(d) / float64(time.Millisecond)})
+		if len(p.recent) > maxRecentLatencies {
+			p.recent = p.recent[len(p.recent)-maxRecentLatencies:]
+		}
+	}
+}
+
+// ServeHTTP implements the /debug/templ/workers diagnostic endpoint,
+// reporting the worker cap in effect, the current queue depth, and the
+// most recent per-file generation latencies.
+func (p *WorkerPolicy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	recent := make([]fileLatency, len(p.recent))
+	copy(recent, p.recent)
+	resp := struct {
+		WorkerCount int           `json:"workerCount"`
+		QueueDepth  int           `json:"queueDepth"`
+		Recent      []fileLatency `json:"recent"`
+	}{
+		WorkerCount: p.count,
+		QueueDepth:  p.queueDepth,
+		Recent:      recent,
+	}
+	p.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// isInteractive reports whether stdout is attached to a terminal, as
+// opposed to e.g. a CI log file or a pipe, so NewWorkerPolicy can tell an
+// interactive edit loop from an automated one.
+func isInteractive() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,46 @@
+//go:build linux
+
+package generatecmd
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// platformCPULimit reads the cgroup v2 CPU quota from /sys/fs/cgroup/cpu.max,
+// returning the number of whole CPUs it permits, rounded up. It reports
+// ok=false if there's no quota in effect (cpu.max reads "max ..."), or the
+// file can't be read, e.g. because the process isn't inside a cgroup v2
+// hierarchy.
+func platformCPULimit() (int, bool) {
+	f, err := os.Open("/sys/fs/cgroup/cpu.max")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return 0, false
+	}
+	fields := strings.Fields(scanner.Text())
+	if len(fields) != 2 || fields[0] == "max" {
+		return 0, false
+	}
+	quota, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	period, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || period == 0 {
+		return 0, false
+	}
+	cpus := int(math.Ceil(quota / period))
+	if cpus < 1 {
+		cpus = 1
+	}
+	return cpus, true
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package generatecmd
+
+// platformCPULimit has no quota to read on this platform: Windows job
+// objects can impose a CPU rate limit analogous to a cgroup quota, but
+// reading one needs the golang.org/x/sys/windows bindings, which aren't a
+// dependency of this module, so it's left unread rather than hand-rolling
+// the syscalls. The darwin/windows interactive halving in NewWorkerPolicy
+// is the mitigation that actually applies outside Linux.
+func platformCPULimit() (int, bool) {
+	return 0, false
+}
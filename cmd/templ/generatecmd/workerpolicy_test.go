@@ -0,0 +1,72 @@
+package generatecmd
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewWorkerPolicyOverride(t *testing.T) {
+	p := NewWorkerPolicy(4)
+	if got := p.Count(); got != 4 {
+		t.Errorf("Count() = %d, want 4", got)
+	}
+}
+
+func TestNewWorkerPolicyDefaultIsAtLeastOne(t *testing.T) {
+	p := NewWorkerPolicy(0)
+	if got := p.Count(); got < 1 {
+		t.Errorf("Count() = %d, want >= 1", got)
+	}
+}
+
+func TestWorkerPolicyBeginRecordsLatency(t *testing.T) {
+	p := NewWorkerPolicy(1)
+	done := p.Begin()
+	done("component.templ", 5*time.Millisecond)
+
+	if len(p.recent) != 1 {
+		t.Fatalf("expected 1 recorded latency, got %d", len(p.recent))
+	}
+	if p.recent[0].FileName != "component.templ" {
+		t.Errorf("recent[0].FileName = %q, want %q", p.recent[0].FileName, "component.templ")
+	}
+	if p.queueDepth != 0 {
+		t.Errorf("queueDepth = %d, want 0 after done is called", p.queueDepth)
+	}
+}
+
+func TestWorkerPolicyBeginCapsRecentLatencies(t *testing.T) {
+	p := NewWorkerPolicy(1)
+	for i := 0; i < maxRecentLatencies+10; i++ {
+		p.Begin()("component.templ", time.Millisecond)
+	}
+	if len(p.recent) != maxRecentLatencies {
+		t.Errorf("len(recent) = %d, want %d", len(p.recent), maxRecentLatencies)
+	}
+}
+
+func TestWorkerPolicyServeHTTP(t *testing.T) {
+	p := NewWorkerPolicy(3)
+	// Leave this one's done func uncalled, so it's still in flight.
+	p.Begin()
+
+	req := httptest.NewRequest("GET", "/debug/templ/workers", nil)
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+
+	var resp struct {
+		WorkerCount int `json:"workerCount"`
+		QueueDepth  int `json:"queueDepth"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.WorkerCount != 3 {
+		t.Errorf("workerCount = %d, want 3", resp.WorkerCount)
+	}
+	if resp.QueueDepth != 1 {
+		t.Errorf("queueDepth = %d, want 1", resp.QueueDepth)
+	}
+}